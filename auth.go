@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AdminUser 是本地用户：用户名 + bcrypt 密码哈希，不持久化明文密码。
+type AdminUser struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+}
+
+// ServerAuthConfig 是 config.json 中的 auth 配置段。
+type ServerAuthConfig struct {
+	JWTSecret       string      `json:"jwt_secret,omitempty"`
+	TokenTTLSeconds int         `json:"token_ttl_seconds,omitempty"`
+	APIKeys         []string    `json:"api_keys,omitempty"`
+	Users           []AdminUser `json:"users,omitempty"`
+	ProtectReads    bool        `json:"protect_reads,omitempty"`
+}
+
+// configured 报告是否配置了任何鉴权方式；未配置时中间件保持开放，向后兼容现有部署。
+func (c ServerAuthConfig) configured() bool {
+	return c.JWTSecret != "" || len(c.APIKeys) > 0 || len(c.Users) > 0
+}
+
+func (c ServerAuthConfig) tokenTTL() time.Duration {
+	if c.TokenTTLSeconds > 0 {
+		return time.Duration(c.TokenTTLSeconds) * time.Second
+	}
+	return time.Hour
+}
+
+type jwtClaims struct {
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// login 校验用户名密码并签发一个 HS256 JWT。
+func (s *Scheduler) login(username, password string) (string, error) {
+	for _, u := range s.auth.Users {
+		if u.Username != username {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+			return "", errInvalidCredentials
+		}
+		now := time.Now()
+		claims := jwtClaims{
+			Username: username,
+			RegisteredClaims: jwt.RegisteredClaims{
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(s.auth.tokenTTL())),
+			},
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(s.auth.JWTSecret))
+	}
+	return "", errInvalidCredentials
+}
+
+// checkBearer 校验 Authorization: Bearer <jwt> 头。
+func (s *Scheduler) checkBearer(c *gin.Context) bool {
+	h := c.GetHeader("Authorization")
+	const prefix = "Bearer "
+	if s.auth.JWTSecret == "" || !strings.HasPrefix(h, prefix) {
+		return false
+	}
+	tokenStr := strings.TrimPrefix(h, prefix)
+	claims := &jwtClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(s.auth.JWTSecret), nil
+	})
+	return err == nil && token.Valid
+}
+
+// checkAPIKey 以常数时间比较 X-API-Key 头与任意一个配置的静态密钥。
+func (s *Scheduler) checkAPIKey(c *gin.Context) bool {
+	key := c.GetHeader("X-API-Key")
+	if key == "" {
+		return false
+	}
+	for _, k := range s.auth.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// requireAuth 返回一个中间件：mandatory=true 时始终要求鉴权（用于增删改路由）；
+// mandatory=false 时仅在 auth.protect_reads 开启时才要求（用于查询路由）。
+// 未配置任何鉴权方式时保持开放，不影响现有部署。
+func (s *Scheduler) requireAuth(mandatory bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.auth.configured() {
+			c.Next()
+			return
+		}
+		if !mandatory && !s.auth.ProtectReads {
+			c.Next()
+			return
+		}
+		if s.checkBearer(c) || s.checkAPIKey(c) {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser 支持标准 5 字段、可选秒的 6 字段，以及 @every/@hourly 等描述符，
+// 语义与 robfig/cron/v3 一致。
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// parseCronSchedule 解析一次 cron 表达式，调用方把结果缓存在 Task 上，供 computeNext 反复调用 Next。
+func parseCronSchedule(expr string) (cron.Schedule, error) {
+	return cronParser.Parse(expr)
+}
+
+// resolveLocation 解析 IANA 时区名；为空时回退到 time.Local。
+func resolveLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(tz)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRunHistory 是每个任务保留的最近运行记录数量。
+const maxRunHistory = 100
+
+// RunRecord 描述一次任务执行（可能包含多次重试尝试）的结果，供 /tasks/:id/runs 查询。
+type RunRecord struct {
+	RunID        string    `json:"run_id"`
+	StartedAt    time.Time `json:"started_at"`
+	DurationMs   int64     `json:"duration_ms"`
+	StatusCode   int       `json:"status_code"`
+	Error        string    `json:"error,omitempty"`
+	AttemptCount int       `json:"attempt_count"`
+}
+
+// runHistory 是按任务维护的有界环形缓冲区，只保留最近 maxRunHistory 条记录。
+type runHistory struct {
+	mu      sync.Mutex
+	records []RunRecord
+}
+
+func (h *runHistory) add(r RunRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	if len(h.records) > maxRunHistory {
+		h.records = h.records[len(h.records)-maxRunHistory:]
+	}
+}
+
+// recent 返回最多 limit 条记录，按时间倒序（最新的在前）。limit<=0 表示返回全部。
+func (h *runHistory) recent(limit int) []RunRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := len(h.records)
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	out := make([]RunRecord, limit)
+	copy(out, h.records[n-limit:])
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
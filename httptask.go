@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// defaultTimeoutSeconds 是未配置 TimeoutSeconds 时单次请求的超时时长。
+const defaultTimeoutSeconds = 10
+
+// allowedMethods 是任务可用的 HTTP 方法白名单，较早期版本的 GET/POST 更宽泛。
+var allowedMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true,
+	"PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// bodyAllowed 报告该方法是否允许携带请求体。
+func bodyAllowed(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return false
+	default:
+		return true
+	}
+}
+
+// AuthConfig 描述任务请求的鉴权方式：basic、bearer 或 hmac（对请求体做 HMAC-SHA256 签名）。
+type AuthConfig struct {
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Secret   string `json:"secret,omitempty"`
+	Header   string `json:"header,omitempty"` // hmac 签名写入的头名，默认 X-Signature
+}
+
+func (a *AuthConfig) validate() error {
+	if a == nil {
+		return nil
+	}
+	switch a.Type {
+	case "basic":
+		if a.Username == "" {
+			return errors.New("basic auth requires username")
+		}
+	case "bearer":
+		if a.Token == "" {
+			return errors.New("bearer auth requires token")
+		}
+	case "hmac":
+		if a.Secret == "" {
+			return errors.New("hmac auth requires secret")
+		}
+	default:
+		return fmt.Errorf("unsupported auth type %q", a.Type)
+	}
+	return nil
+}
+
+// apply 把鉴权信息写入请求；hmac 模式对已编码好的请求体签名。
+func (a *AuthConfig) apply(req *http.Request, body []byte) {
+	if a == nil {
+		return
+	}
+	switch a.Type {
+	case "basic":
+		req.SetBasicAuth(a.Username, a.Password)
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case "hmac":
+		mac := hmac.New(sha256.New, []byte(a.Secret))
+		mac.Write(body)
+		header := a.Header
+		if header == "" {
+			header = "X-Signature"
+		}
+		req.Header.Set(header, hex.EncodeToString(mac.Sum(nil)))
+	}
+}
+
+// ExpectedStatus 允许按固定状态码列表或正则表达式判定一次执行是否成功。
+type ExpectedStatus struct {
+	Codes []int  `json:"codes,omitempty"`
+	Regex string `json:"regex,omitempty"`
+	re    *regexp.Regexp
+}
+
+func (e *ExpectedStatus) compile() error {
+	if e == nil || e.Regex == "" {
+		return nil
+	}
+	re, err := regexp.Compile(e.Regex)
+	if err != nil {
+		return err
+	}
+	e.re = re
+	return nil
+}
+
+// matches 返回 (是否匹配, 是否配置过)；未配置时调用方应回退到默认的 2xx/3xx 判定。
+func (e *ExpectedStatus) matches(statusCode int) (matched bool, configured bool) {
+	if e == nil {
+		return false, false
+	}
+	if e.re != nil {
+		return e.re.MatchString(strconv.Itoa(statusCode)), true
+	}
+	if len(e.Codes) > 0 {
+		for _, c := range e.Codes {
+			if c == statusCode {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	return false, false
+}
+
+// classifyWithExpected 在 classifyOutcome 的基础上叠加 ExpectedStatus：
+// 命中期望状态码时即视为成功（即便按默认规则本会重试），未命中时至少不被当作成功。
+func classifyWithExpected(statusCode int, err error, expected *ExpectedStatus) outcomeClass {
+	base := classifyOutcome(statusCode, err)
+	matched, configured := expected.matches(statusCode)
+	if !configured {
+		return base
+	}
+	if matched {
+		return outcomeSuccess
+	}
+	if base == outcomeSuccess {
+		return outcomeClientError
+	}
+	return base
+}
+
+// decodeBody 按 encoding（""/"base64"）解码任务配置的请求体。
+func decodeBody(body, encoding string) ([]byte, error) {
+	if body == "" {
+		return nil, nil
+	}
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/json"
@@ -12,69 +13,146 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/panjf2000/ants/v2"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/robfig/cron/v3"
 )
 
 // -------------------- 数据结构 --------------------
 
 type AddTaskReq struct {
-	IntervalSeconds int    `json:"interval_seconds" binding:"required,min=1,max=86400"`
-	URL             string `json:"url"              binding:"required,url"`
-	Method          string `json:"method"           binding:"required"`
-	Description     string `json:"description"      binding:"required,min=1,max=200"`
+	IntervalSeconds int                 `json:"interval_seconds" binding:"required_without=CronExpr,omitempty,min=1,max=86400"`
+	CronExpr        string              `json:"cron"             binding:"required_without=IntervalSeconds"`
+	Timezone        string              `json:"timezone"`
+	URL             string              `json:"url"              binding:"required,url"`
+	Method          string              `json:"method"           binding:"required"`
+	Description     string              `json:"description"      binding:"required,min=1,max=200"`
+	Retry           *RetryPolicyConfig  `json:"retry"`
+	Headers         map[string]string   `json:"headers"`
+	Body            string              `json:"body"`
+	BodyEncoding    string              `json:"body_encoding"` // "" 或 "base64"
+	BodyContentType string              `json:"body_content_type"`
+	ExpectedStatus  *ExpectedStatus     `json:"expected_status"`
+	TimeoutSeconds  int                 `json:"timeout_seconds" binding:"omitempty,min=1,max=300"`
+	Auth            *AuthConfig         `json:"auth"`
+	MaxInFlight     int                 `json:"max_in_flight" binding:"omitempty,min=1"`
+	OverlapPolicy   string              `json:"overlap_policy"` // "skip"（默认）或 "queue"
+	Notify          *NotifyConfig       `json:"notify"`
 }
 
 type Task struct {
 	ID              string
 	IntervalSeconds int
+	CronExpr        string
+	Timezone        string
 	URL             string
 	Method          string
 	Description     string
+	RetryPolicy     RetryPolicy
+	Headers         map[string]string
+	Body            string
+	BodyEncoding    string
+	BodyContentType string
+	ExpectedStatus  *ExpectedStatus
+	TimeoutSeconds  int
+	Auth            *AuthConfig
+	MaxInFlight     int
+	OverlapPolicy   string
+	Notify          *NotifyConfig
 	paused          bool
 	startedAt       time.Time
 	runCount        uint64
+	ctx             context.Context
 	cancel          context.CancelFunc
+	schedule        cron.Schedule // 仅 CronExpr 非空时缓存
+	loc             *time.Location
+	history         *runHistory
+	sem             chan struct{} // 容量为 MaxInFlight，限制同一任务的并发执行数
+	health          *taskHealth
 }
 
 type persistedTask struct {
-	ID              string `json:"id"`
-	IntervalSeconds int    `json:"interval_seconds"`
-	URL             string `json:"url"`
-	Method          string `json:"method"`
-	Description     string `json:"description"`
-	Enabled         bool   `json:"enabled"`
-	CreatedAt       string `json:"created_at,omitempty"`
-	UpdatedAt       string `json:"updated_at,omitempty"`
+	ID              string             `json:"id"`
+	IntervalSeconds int                `json:"interval_seconds,omitempty"`
+	CronExpr        string             `json:"cron,omitempty"`
+	Timezone        string             `json:"timezone,omitempty"`
+	URL             string             `json:"url"`
+	Method          string             `json:"method"`
+	Description     string             `json:"description"`
+	Retry           *RetryPolicyConfig `json:"retry,omitempty"`
+	Headers         map[string]string  `json:"headers,omitempty"`
+	Body            string             `json:"body,omitempty"`
+	BodyEncoding    string             `json:"body_encoding,omitempty"`
+	BodyContentType string             `json:"body_content_type,omitempty"`
+	ExpectedStatus  *ExpectedStatus    `json:"expected_status,omitempty"`
+	TimeoutSeconds  int                `json:"timeout_seconds,omitempty"`
+	Auth            *AuthConfig        `json:"auth,omitempty"`
+	MaxInFlight     int                `json:"max_in_flight,omitempty"`
+	OverlapPolicy   string             `json:"overlap_policy,omitempty"`
+	Notify          *NotifyConfig      `json:"notify,omitempty"`
+	Enabled         bool               `json:"enabled"`
+	CreatedAt       string             `json:"created_at,omitempty"`
+	UpdatedAt       string             `json:"updated_at,omitempty"`
+
+	// 健康状态计数器，持久化以避免重启后针对已处于 unhealthy 的任务重新触发告警。
+	HealthUnhealthy   bool   `json:"health_unhealthy,omitempty"`
+	HealthFailures    int    `json:"health_failures,omitempty"`
+	HealthSuccesses   int    `json:"health_successes,omitempty"`
+	HealthLastStatus  int    `json:"health_last_status,omitempty"`
+	HealthLastError   string `json:"health_last_error,omitempty"`
+	HealthLastAlertAt string `json:"health_last_alert_at,omitempty"`
 }
 
 type persistedFile struct {
-	Version int             `json:"version"`
-	Port    int             `json:"port"`
-	Tasks   []persistedTask `json:"tasks"`
+	Version        int              `json:"version"`
+	Port           int              `json:"port"`
+	MaxConcurrency int              `json:"max_concurrency,omitempty"`
+	Auth           ServerAuthConfig `json:"auth,omitempty"`
+	Tasks          []persistedTask  `json:"tasks"`
 }
 
 // -------------------- 调度器 --------------------
 
 type Scheduler struct {
-	mu       sync.RWMutex
-	tasks    map[string]*Task
-	client   *http.Client
-	idSeq    uint64
-	filePath string
-	port     int
+	mu             sync.RWMutex
+	tasks          map[string]*Task
+	client         *http.Client
+	idSeq          uint64
+	filePath       string
+	port           int
+	pool           *ants.Pool
+	maxConcurrency int
+	heapItems      taskHeap
+	heapIdx        map[string]*heapItem
+	wake           chan struct{}
+	auth           ServerAuthConfig
 }
 
 func NewScheduler(filePath string) *Scheduler {
+	maxConcurrency := defaultMaxConcurrency
+	// Nonblocking：池子打满时 Submit 立即返回 ErrPoolOverload，而不是阻塞调用方——
+	// dispatchDue 是单个定时器协程的唯一调用者，阻塞在 Submit 上会卡住整个调度循环。
+	pool, err := ants.NewPool(maxConcurrency, ants.WithNonblocking(true))
+	if err != nil {
+		log.Fatalf("create worker pool: %v", err)
+	}
 	return &Scheduler{
-		tasks:    make(map[string]*Task),
-		client:   &http.Client{Timeout: 10 * time.Second},
-		filePath: filePath,
-		port:     9000, // 默认端口
+		tasks:          make(map[string]*Task),
+		client:         &http.Client{}, // 不设 Timeout：每次请求的超时完全由下面的 context deadline 控制
+		filePath:       filePath,
+		port:           9000, // 默认端口
+		pool:           pool,
+		maxConcurrency: maxConcurrency,
+		heapIdx:        make(map[string]*heapItem),
+		wake:           make(chan struct{}, 1),
 	}
 }
 
@@ -114,22 +192,47 @@ func atomicWriteJSON(path string, v any) error {
 }
 
 func (s *Scheduler) saveToDiskLocked() error {
-	out := persistedFile{Version: 1, Port: s.port}
+	out := persistedFile{Version: 1, Port: s.port, MaxConcurrency: s.maxConcurrency, Auth: s.auth}
 	now := time.Now().UTC().Format(time.RFC3339Nano)
 	for _, t := range s.tasks {
 		created := t.startedAt
 		if created.IsZero() {
 			created = time.Now().UTC()
 		}
+		retry := t.RetryPolicy.toConfig()
+		unhealthy, failures, successes, lastStatus, lastError, lastAlertAt := t.health.snapshot()
+		lastAlertStr := ""
+		if !lastAlertAt.IsZero() {
+			lastAlertStr = lastAlertAt.UTC().Format(time.RFC3339Nano)
+		}
 		out.Tasks = append(out.Tasks, persistedTask{
-			ID:              t.ID,
-			IntervalSeconds: t.IntervalSeconds,
-			URL:             t.URL,
-			Method:          t.Method,
-			Description:     t.Description,
-			Enabled:         !t.paused,
-			CreatedAt:       created.Format(time.RFC3339Nano),
-			UpdatedAt:       now,
+			ID:                t.ID,
+			IntervalSeconds:   t.IntervalSeconds,
+			CronExpr:          t.CronExpr,
+			Timezone:          t.Timezone,
+			URL:               t.URL,
+			Method:            t.Method,
+			Description:       t.Description,
+			Retry:             &retry,
+			Headers:           t.Headers,
+			Body:              t.Body,
+			BodyEncoding:      t.BodyEncoding,
+			BodyContentType:   t.BodyContentType,
+			ExpectedStatus:    t.ExpectedStatus,
+			TimeoutSeconds:    t.TimeoutSeconds,
+			Auth:              t.Auth,
+			MaxInFlight:       t.MaxInFlight,
+			OverlapPolicy:     t.OverlapPolicy,
+			Notify:            t.Notify,
+			Enabled:           !t.paused,
+			CreatedAt:         created.Format(time.RFC3339Nano),
+			UpdatedAt:         now,
+			HealthUnhealthy:   unhealthy,
+			HealthFailures:    failures,
+			HealthSuccesses:   successes,
+			HealthLastStatus:  lastStatus,
+			HealthLastError:   lastError,
+			HealthLastAlertAt: lastAlertStr,
 		})
 	}
 	sort.Slice(out.Tasks, func(i, j int) bool { return out.Tasks[i].ID < out.Tasks[j].ID })
@@ -157,39 +260,109 @@ func (s *Scheduler) loadFromDisk() error {
 	if pf.Port > 0 {
 		s.port = pf.Port
 	}
-	log.Printf("using port: %d", s.port)
+	if pf.MaxConcurrency > 0 {
+		s.maxConcurrency = pf.MaxConcurrency
+		s.pool.Tune(s.maxConcurrency)
+	}
+	s.auth = pf.Auth
+	log.Printf("using port: %d, max_concurrency: %d, auth_configured: %v", s.port, s.maxConcurrency, s.auth.configured())
 
 	// 恢复任务
 	restored := 0
 	for _, pt := range pf.Tasks {
 		method := strings.ToUpper(strings.TrimSpace(pt.Method))
-		if method != "GET" && method != "POST" {
+		if !allowedMethods[method] {
 			log.Printf("[restore] skip %s: invalid method=%q", pt.ID, pt.Method)
 			continue
 		}
-		if pt.IntervalSeconds < 1 || strings.TrimSpace(pt.URL) == "" {
-			log.Printf("[restore] skip %s: invalid interval/url", pt.ID)
+		if strings.TrimSpace(pt.URL) == "" {
+			log.Printf("[restore] skip %s: invalid url", pt.ID)
+			continue
+		}
+		if pt.CronExpr == "" && pt.IntervalSeconds < 1 {
+			log.Printf("[restore] skip %s: invalid interval/cron", pt.ID)
+			continue
+		}
+		if pt.Body != "" && !bodyAllowed(method) {
+			log.Printf("[restore] skip %s: method %s does not accept a body", pt.ID, method)
+			continue
+		}
+		if err := pt.ExpectedStatus.compile(); err != nil {
+			log.Printf("[restore] skip %s: invalid expected_status: %v", pt.ID, err)
+			continue
+		}
+		if err := pt.Auth.validate(); err != nil {
+			log.Printf("[restore] skip %s: invalid auth: %v", pt.ID, err)
+			continue
+		}
+		if err := pt.Notify.validate(); err != nil {
+			log.Printf("[restore] skip %s: invalid notify: %v", pt.ID, err)
 			continue
 		}
+		maxInFlight := pt.MaxInFlight
+		if maxInFlight < 1 {
+			maxInFlight = 1
+		}
+		var lastAlertAt time.Time
+		if pt.HealthLastAlertAt != "" {
+			if ts, err := time.Parse(time.RFC3339Nano, pt.HealthLastAlertAt); err == nil {
+				lastAlertAt = ts
+			}
+		}
 		ctx, cancel := context.WithCancel(context.Background())
 		t := &Task{
 			ID:              pt.ID,
 			IntervalSeconds: pt.IntervalSeconds,
+			CronExpr:        pt.CronExpr,
+			Timezone:        pt.Timezone,
 			URL:             pt.URL,
 			Method:          method,
 			Description:     pt.Description,
+			RetryPolicy:     pt.Retry.toRetryPolicy(),
+			Headers:         pt.Headers,
+			Body:            pt.Body,
+			BodyEncoding:    pt.BodyEncoding,
+			BodyContentType: pt.BodyContentType,
+			ExpectedStatus:  pt.ExpectedStatus,
+			TimeoutSeconds:  pt.TimeoutSeconds,
+			Auth:            pt.Auth,
+			MaxInFlight:     maxInFlight,
+			OverlapPolicy:   normalizeOverlapPolicy(pt.OverlapPolicy),
+			Notify:          pt.Notify,
 			paused:          !pt.Enabled,
 			startedAt:       time.Now().UTC(),
+			ctx:             ctx,
+			cancel:          cancel,
+			history:         &runHistory{},
+			sem:             make(chan struct{}, maxInFlight),
+			health:          newTaskHealth(pt.HealthUnhealthy, pt.HealthFailures, pt.HealthSuccesses, pt.HealthLastStatus, pt.HealthLastError, lastAlertAt),
+		}
+		if pt.CronExpr != "" {
+			sched, err := parseCronSchedule(pt.CronExpr)
+			if err != nil {
+				log.Printf("[restore] skip %s: invalid cron=%q: %v", pt.ID, pt.CronExpr, err)
+				cancel()
+				continue
+			}
+			loc, err := resolveLocation(pt.Timezone)
+			if err != nil {
+				log.Printf("[restore] skip %s: invalid timezone=%q: %v", pt.ID, pt.Timezone, err)
+				cancel()
+				continue
+			}
+			t.schedule = sched
+			t.loc = loc
 		}
+		s.mu.Lock()
 		s.tasks[t.ID] = t
 		if pt.Enabled {
-			t.cancel = cancel
-			go s.runTask(ctx, t)
+			s.scheduleLocked(t, time.Now())
 			log.Printf("[restore] RUNNING id=%s %s %s every %ds", t.ID, t.Method, t.URL, t.IntervalSeconds)
 		} else {
 			cancel()
 			log.Printf("[restore] PAUSED  id=%s %s %s every %ds", t.ID, t.Method, t.URL, t.IntervalSeconds)
 		}
+		s.mu.Unlock()
 		restored++
 	}
 	log.Printf("[restore] done: restored=%d, file=%s", restored, s.filePath)
@@ -200,36 +373,88 @@ func (s *Scheduler) loadFromDisk() error {
 
 func (s *Scheduler) AddTask(req AddTaskReq) (string, error) {
 	method := strings.ToUpper(strings.TrimSpace(req.Method))
-	if method != "GET" && method != "POST" {
-		return "", errors.New("method must be GET or POST")
+	if !allowedMethods[method] {
+		return "", fmt.Errorf("unsupported method %q", req.Method)
 	}
-	if req.IntervalSeconds < 1 {
+	if req.Body != "" && !bodyAllowed(method) {
+		return "", fmt.Errorf("method %s does not accept a request body", method)
+	}
+	if err := req.ExpectedStatus.compile(); err != nil {
+		return "", fmt.Errorf("invalid expected_status regex: %v", err)
+	}
+	if err := req.Auth.validate(); err != nil {
+		return "", fmt.Errorf("invalid auth: %v", err)
+	}
+	if err := req.Notify.validate(); err != nil {
+		return "", fmt.Errorf("invalid notify: %v", err)
+	}
+
+	var (
+		sched cron.Schedule
+		loc   *time.Location
+	)
+	if req.CronExpr != "" {
+		var err error
+		sched, err = parseCronSchedule(req.CronExpr)
+		if err != nil {
+			return "", fmt.Errorf("invalid cron expression: %v", err)
+		}
+		loc, err = resolveLocation(req.Timezone)
+		if err != nil {
+			return "", fmt.Errorf("invalid timezone: %v", err)
+		}
+	} else if req.IntervalSeconds < 1 {
 		return "", errors.New("interval_seconds must be >= 1")
 	}
 
+	maxInFlight := req.MaxInFlight
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+
 	id := s.nextID()
+	ctx, cancel := context.WithCancel(context.Background())
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	t := &Task{
 		ID:              id,
 		IntervalSeconds: req.IntervalSeconds,
+		CronExpr:        req.CronExpr,
+		Timezone:        req.Timezone,
 		URL:             req.URL,
 		Method:          method,
 		Description:     strings.TrimSpace(req.Description),
+		RetryPolicy:     req.Retry.toRetryPolicy(),
+		Headers:         req.Headers,
+		Body:            req.Body,
+		BodyEncoding:    req.BodyEncoding,
+		BodyContentType: req.BodyContentType,
+		ExpectedStatus:  req.ExpectedStatus,
+		TimeoutSeconds:  req.TimeoutSeconds,
+		Auth:            req.Auth,
+		MaxInFlight:     maxInFlight,
+		OverlapPolicy:   normalizeOverlapPolicy(req.OverlapPolicy),
+		Notify:          req.Notify,
 		paused:          false,
 		startedAt:       time.Now().UTC(),
+		ctx:             ctx,
+		cancel:          cancel,
+		schedule:        sched,
+		loc:             loc,
+		history:         &runHistory{},
+		sem:             make(chan struct{}, maxInFlight),
+		health:          newTaskHealth(false, 0, 0, 0, "", time.Time{}),
 	}
 	s.tasks[id] = t
 
 	if err := s.saveToDiskLocked(); err != nil {
 		delete(s.tasks, id)
+		cancel()
 		return "", err
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	t.cancel = cancel
-	go s.runTask(ctx, t)
+	s.scheduleLocked(t, time.Now())
 	return id, nil
 }
 
@@ -244,7 +469,9 @@ func (s *Scheduler) RemoveTask(id string) bool {
 		t.cancel()
 		t.cancel = nil
 	}
+	s.unscheduleLocked(id)
 	delete(s.tasks, id)
+	deleteTaskMetrics(id)
 	_ = s.saveToDiskLocked()
 	return true
 }
@@ -263,6 +490,7 @@ func (s *Scheduler) PauseTask(id string) bool {
 		t.cancel()
 		t.cancel = nil
 	}
+	s.unscheduleLocked(id)
 	t.paused = true
 	_ = s.saveToDiskLocked()
 	return true
@@ -270,42 +498,131 @@ func (s *Scheduler) PauseTask(id string) bool {
 
 // -------------------- 执行循环 --------------------
 
-func (s *Scheduler) runTask(ctx context.Context, t *Task) {
-	// 立即执行一次（如不需要可注释）
-	s.executeOnce(t)
-
-	ticker := time.NewTicker(time.Duration(t.IntervalSeconds) * time.Second)
-	defer ticker.Stop()
+func (s *Scheduler) executeOnce(t *Task) {
+	policy := t.RetryPolicy
+	if policy.MaxAttempts < 1 {
+		policy = defaultRetryPolicy()
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			log.Printf("[task %s] stopped", t.ID)
-			return
-		case <-ticker.C:
-			s.executeOnce(t)
+	runID := uuid.NewString()
+	startedAt := time.Now().UTC()
+	tasksRunningGauge.Inc()
+	defer tasksRunningGauge.Dec()
+
+	record := func(success bool, statusCode int, errStr string, attempts int) {
+		dur := time.Since(startedAt)
+		taskExecutionDuration.WithLabelValues(t.ID, t.Method).Observe(dur.Seconds())
+		taskExecutionsTotal.WithLabelValues(t.ID, t.Method, statusClass(statusCode)).Inc()
+		if attempts > 1 {
+			taskRetriesTotal.WithLabelValues(t.ID).Add(float64(attempts - 1))
+		}
+		t.history.add(RunRecord{
+			RunID:        runID,
+			StartedAt:    startedAt,
+			DurationMs:   dur.Milliseconds(),
+			StatusCode:   statusCode,
+			Error:        errStr,
+			AttemptCount: attempts,
+		})
+		transitioned, unhealthy := t.health.record(success, statusCode, errStr, t.Notify.failureThreshold(), t.Notify.recoveryThreshold())
+		if transitioned {
+			// 健康状态跃迁时立即落盘，重启后不会对已处于 unhealthy 的任务重新告警。
+			s.mu.Lock()
+			_ = s.saveToDiskLocked()
+			s.mu.Unlock()
+		}
+		if transitioned && t.Notify != nil && t.Notify.URL != "" {
+			go s.fireNotify(t, unhealthy, statusCode, errStr)
 		}
 	}
-}
 
-func (s *Scheduler) executeOnce(t *Task) {
-	req, err := http.NewRequest(t.Method, t.URL, nil) // POST 无 body
+	bodyBytes, err := decodeBody(t.Body, t.BodyEncoding)
 	if err != nil {
-		log.Printf("[task %s] build request error: %v", t.ID, err)
+		log.Printf("[task %s run=%s] decode body error: %v", t.ID, runID, err)
+		record(false, 0, err.Error(), 0)
 		return
 	}
-	req.Header.Set("User-Agent", "gin-scheduler/1.7")
-	resp, err := s.client.Do(req)
-	if err != nil {
-		log.Printf("[task %s] request error: %v", t.ID, err)
-		return
-	}
-	if resp.Body != nil {
-		_, _ = io.Copy(io.Discard, resp.Body)
-		_ = resp.Body.Close()
+
+	var lastErr error
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		var bodyReader io.Reader
+		if bodyBytes != nil {
+			bodyReader = bytes.NewReader(bodyBytes)
+		}
+		req, err := http.NewRequest(t.Method, t.URL, bodyReader)
+		if err != nil {
+			log.Printf("[task %s run=%s] build request error: %v", t.ID, runID, err)
+			record(false, 0, err.Error(), attempt+1)
+			return
+		}
+		req.Header.Set("User-Agent", "gin-scheduler/1.7")
+		if t.BodyContentType != "" {
+			req.Header.Set("Content-Type", t.BodyContentType)
+		}
+		for k, v := range t.Headers {
+			req.Header.Set(k, v)
+		}
+		t.Auth.apply(req, bodyBytes)
+
+		timeoutSeconds := t.TimeoutSeconds
+		if timeoutSeconds <= 0 {
+			timeoutSeconds = defaultTimeoutSeconds
+		}
+		attemptCtx, cancel := context.WithTimeout(t.ctx, time.Duration(timeoutSeconds)*time.Second)
+		resp, err := s.client.Do(req.WithContext(attemptCtx))
+		cancel()
+
+		statusCode := 0
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+		class := classifyWithExpected(statusCode, err, t.ExpectedStatus)
+
+		if resp != nil && resp.Body != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		switch class {
+		case outcomeSuccess:
+			atomic.AddUint64(&t.runCount, 1)
+			log.Printf("[task %s run=%s] %s %s -> %s (attempt=%d run=%d)", t.ID, runID, t.Method, t.URL, resp.Status, attempt+1, atomic.LoadUint64(&t.runCount))
+			record(true, statusCode, "", attempt+1)
+			return
+		case outcomeClientError:
+			atomic.AddUint64(&t.runCount, 1)
+			status := fmt.Sprintf("%d", statusCode)
+			if resp != nil {
+				status = resp.Status
+			}
+			log.Printf("[task %s run=%s] %s %s -> %s (attempt=%d, client error or unexpected status, no retry)", t.ID, runID, t.Method, t.URL, status, attempt+1)
+			record(false, statusCode, "", attempt+1)
+			return
+		default: // outcomeTransient
+			lastErr = err
+			if attempt == policy.MaxAttempts-1 {
+				continue // 最后一次尝试失败，跳出循环后统一记录
+			}
+			wait := policy.backoff(attempt)
+			if d, ok := retryAfterDelay(resp); ok {
+				wait = d
+			}
+			log.Printf("[task %s run=%s] attempt %d/%d transient failure (status=%d err=%v), retrying in %s", t.ID, runID, attempt+1, policy.MaxAttempts, statusCode, err, wait)
+			select {
+			case <-time.After(wait):
+			case <-t.ctx.Done():
+				log.Printf("[task %s run=%s] cancelled during retry backoff", t.ID, runID)
+				return
+			}
+		}
 	}
 	atomic.AddUint64(&t.runCount, 1)
-	log.Printf("[task %s] %s %s -> %s (run=%d)", t.ID, t.Method, t.URL, resp.Status, atomic.LoadUint64(&t.runCount))
+	errStr := ""
+	if lastErr != nil {
+		errStr = lastErr.Error()
+	}
+	log.Printf("[task %s run=%s] %s %s failed after %d attempts: %v", t.ID, runID, t.Method, t.URL, policy.MaxAttempts, lastErr)
+	record(false, 0, errStr, policy.MaxAttempts)
 }
 
 // -------------------- 入口 & 路由 --------------------
@@ -321,14 +638,35 @@ func main() {
 	if err := s.loadFromDisk(); err != nil {
 		log.Printf("load error: %v", err)
 	}
+	go s.Run(context.Background())
 
-	// —— 查询：唯一使用 GET ——
-	r.GET("/tasks", func(c *gin.Context) {
+	// 登录：凭用户名密码换取 JWT，未配置 auth.users 时恒返回 401
+	r.POST("/auth/login", func(c *gin.Context) {
+		var req struct {
+			Username string `json:"username" binding:"required"`
+			Password string `json:"password" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		token, err := s.login(req.Username, req.Password)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"token": token})
+	})
+
+	// —— 查询：唯一使用 GET ——（mandatory=false：仅当 auth.protect_reads 开启时才要求鉴权）
+	r.GET("/tasks", s.requireAuth(false), func(c *gin.Context) {
 		s.mu.RLock()
 		defer s.mu.RUnlock()
 		type view struct {
 			ID              string `json:"id"`
-			IntervalSeconds int    `json:"interval_seconds"`
+			IntervalSeconds int    `json:"interval_seconds,omitempty"`
+			CronExpr        string `json:"cron,omitempty"`
+			Timezone        string `json:"timezone,omitempty"`
 			URL             string `json:"url"`
 			Method          string `json:"method"`
 			Description     string `json:"description"`
@@ -343,6 +681,8 @@ func main() {
 			out = append(out, view{
 				ID:              t.ID,
 				IntervalSeconds: t.IntervalSeconds,
+				CronExpr:        t.CronExpr,
+				Timezone:        t.Timezone,
 				URL:             t.URL,
 				Method:          t.Method,
 				Description:     t.Description,
@@ -353,7 +693,7 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{"tasks": out})
 	})
 
-	r.GET("/tasks/:id", func(c *gin.Context) {
+	r.GET("/tasks/:id", s.requireAuth(false), func(c *gin.Context) {
 		id := c.Param("id")
 		s.mu.RLock()
 		t, ok := s.tasks[id]
@@ -369,6 +709,8 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{
 			"id":               t.ID,
 			"interval_seconds": t.IntervalSeconds,
+			"cron":             t.CronExpr,
+			"timezone":         t.Timezone,
 			"url":              t.URL,
 			"method":           t.Method,
 			"description":      t.Description,
@@ -376,8 +718,61 @@ func main() {
 		})
 	})
 
-	// 添加任务（唯一需要 JSON 请求体）
-	r.POST("/tasks/add", func(c *gin.Context) {
+	// 任务运行历史（最近 maxRunHistory 条，可通过 limit 截断）
+	r.GET("/tasks/:id/runs", s.requireAuth(false), func(c *gin.Context) {
+		id := c.Param("id")
+		s.mu.RLock()
+		t, ok := s.tasks[id]
+		s.mu.RUnlock()
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+		limit := 0
+		if v := c.Query("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+				return
+			}
+			limit = n
+		}
+		c.JSON(http.StatusOK, gin.H{"runs": t.history.recent(limit)})
+	})
+
+	// 任务健康状态（连续失败/成功计数、是否处于 unhealthy、最近一次告警时间）
+	r.GET("/tasks/:id/health", s.requireAuth(false), func(c *gin.Context) {
+		id := c.Param("id")
+		s.mu.RLock()
+		t, ok := s.tasks[id]
+		s.mu.RUnlock()
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+		unhealthy, failures, successes, lastStatus, lastError, lastAlertAt := t.health.snapshot()
+		resp := gin.H{
+			"task_id":               t.ID,
+			"unhealthy":             unhealthy,
+			"consecutive_failures":  failures,
+			"consecutive_successes": successes,
+			"last_status_code":      lastStatus,
+			"run_count":             atomic.LoadUint64(&t.runCount),
+		}
+		if lastError != "" {
+			resp["last_error"] = lastError
+		}
+		if !lastAlertAt.IsZero() {
+			resp["last_alert_at"] = lastAlertAt.UTC().Format(time.RFC3339Nano)
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	// Prometheus 指标
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// 添加任务（需要 JSON 请求体）；增删改路由恒要求鉴权（一旦配置了 auth）
+	r.POST("/tasks/add", s.requireAuth(true), func(c *gin.Context) {
 		var req AddTaskReq
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -392,7 +787,7 @@ func main() {
 	})
 
 	// 暂停任务（无请求体）
-	r.POST("/tasks/:id/pause", func(c *gin.Context) {
+	r.POST("/tasks/:id/pause", s.requireAuth(true), func(c *gin.Context) {
 		id := c.Param("id")
 		if !s.PauseTask(id) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
@@ -402,7 +797,7 @@ func main() {
 	})
 
 	// 删除任务（无请求体）
-	r.POST("/tasks/:id/delete", func(c *gin.Context) {
+	r.POST("/tasks/:id/delete", s.requireAuth(true), func(c *gin.Context) {
 		id := c.Param("id")
 		if !s.RemoveTask(id) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// -------------------- Prometheus 指标 --------------------
+
+var (
+	taskExecutionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scheduler_task_executions_total",
+		Help: "Total number of task executions, by outcome.",
+	}, []string{"task_id", "method", "status_class"})
+
+	taskExecutionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scheduler_task_execution_duration_seconds",
+		Help:    "Duration of a task execution (including retries), in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"task_id", "method"})
+
+	taskRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scheduler_task_retries_total",
+		Help: "Total number of retry attempts performed across all task executions.",
+	}, []string{"task_id"})
+
+	tasksRunningGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "scheduler_tasks_running",
+		Help: "Number of task executions currently in flight.",
+	})
+)
+
+// deleteTaskMetrics 清除某个 task_id 在所有按任务打标签的指标上的全部序列。
+// 任务 ID 由 nextID 生成、永不复用，若 RemoveTask 不清理，这些序列会随着
+// 任务增删无限增长，最终拖垮默认 Registry 和 /metrics 的抓取。
+func deleteTaskMetrics(taskID string) {
+	labels := prometheus.Labels{"task_id": taskID}
+	taskExecutionsTotal.DeletePartialMatch(labels)
+	taskExecutionDuration.DeletePartialMatch(labels)
+	taskRetriesTotal.DeletePartialMatch(labels)
+}
+
+// statusClass 把状态码归并为 Prometheus 标签，网络错误等无状态码的情形记为 "error"。
+func statusClass(statusCode int) string {
+	if statusCode <= 0 {
+		return "error"
+	}
+	return fmt.Sprintf("%dxx", statusCode/100)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultNotifyTemplate 在任务未自定义 template 时使用。
+const defaultNotifyTemplate = "[{state}] task {task_id} ({description}) {url} last_status={status} last_error={error} run_count={run_count}"
+
+// NotifyConfig 描述任务健康状态变化时要触发的 webhook 告警。
+type NotifyConfig struct {
+	URL                string `json:"url"`
+	Shape              string `json:"shape,omitempty"` // ""/"generic"（默认）、"dingtalk"、"slack"
+	FailureThreshold   int    `json:"failure_threshold,omitempty"`
+	RecoveryThreshold  int    `json:"recovery_threshold,omitempty"`
+	MinIntervalSeconds int    `json:"min_interval_seconds,omitempty"` // 同一任务两次告警之间的最小间隔，抑制抖动
+	Template           string `json:"template,omitempty"`
+}
+
+func (c *NotifyConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	if strings.TrimSpace(c.URL) == "" {
+		return errors.New("notify requires a url")
+	}
+	switch c.Shape {
+	case "", "generic", "dingtalk", "slack":
+	default:
+		return fmt.Errorf("unsupported notify shape %q", c.Shape)
+	}
+	return nil
+}
+
+func (c *NotifyConfig) failureThreshold() int {
+	if c == nil || c.FailureThreshold < 1 {
+		return 3
+	}
+	return c.FailureThreshold
+}
+
+func (c *NotifyConfig) recoveryThreshold() int {
+	if c == nil || c.RecoveryThreshold < 1 {
+		return 1
+	}
+	return c.RecoveryThreshold
+}
+
+func (c *NotifyConfig) minInterval() time.Duration {
+	if c == nil || c.MinIntervalSeconds < 1 {
+		return 5 * time.Minute
+	}
+	return time.Duration(c.MinIntervalSeconds) * time.Second
+}
+
+// renderMessage 用 {task_id}/{description}/{url}/{state}/{status}/{error}/{run_count} 占位符渲染告警文案。
+func (c *NotifyConfig) renderMessage(t *Task, state string, statusCode int, errStr string) string {
+	tmpl := defaultNotifyTemplate
+	if c != nil && c.Template != "" {
+		tmpl = c.Template
+	}
+	replacer := strings.NewReplacer(
+		"{task_id}", t.ID,
+		"{description}", t.Description,
+		"{url}", t.URL,
+		"{state}", state,
+		"{status}", strconv.Itoa(statusCode),
+		"{error}", errStr,
+		"{run_count}", strconv.FormatUint(atomic.LoadUint64(&t.runCount), 10),
+	)
+	return replacer.Replace(tmpl)
+}
+
+// notifyEvent 是通用 shape 下直接投递的结构化告警内容。
+type notifyEvent struct {
+	TaskID      string `json:"task_id"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	State       string `json:"state"` // "unhealthy" 或 "healthy"
+	StatusCode  int    `json:"status_code"`
+	Error       string `json:"error,omitempty"`
+	RunCount    uint64 `json:"run_count"`
+	Message     string `json:"message"`
+}
+
+// shape 按配置的 Shape 把 notifyEvent 包装成目标系统期望的报文；
+// "" 与 "generic" 原样投递结构化事件，"dingtalk"/"slack" 只投递一条文本消息。
+func (c *NotifyConfig) shape(ev notifyEvent) any {
+	shape := ""
+	if c != nil {
+		shape = c.Shape
+	}
+	switch shape {
+	case "dingtalk":
+		return map[string]any{
+			"msgtype": "text",
+			"text":    map[string]string{"content": ev.Message},
+		}
+	case "slack":
+		return map[string]string{"text": ev.Message}
+	default:
+		return ev
+	}
+}
+
+// taskHealth 跟踪单个任务的连续成功/失败次数与健康状态，加锁独立于 Scheduler.mu，
+// 因为它在协程池的工作协程里随每次执行更新。
+type taskHealth struct {
+	mu                   sync.Mutex
+	unhealthy            bool
+	consecutiveFailures  int
+	consecutiveSuccesses int
+	lastStatusCode       int
+	lastError            string
+	lastAlertAt          time.Time
+}
+
+func newTaskHealth(unhealthy bool, failures, successes, lastStatus int, lastError string, lastAlertAt time.Time) *taskHealth {
+	return &taskHealth{
+		unhealthy:            unhealthy,
+		consecutiveFailures:  failures,
+		consecutiveSuccesses: successes,
+		lastStatusCode:       lastStatus,
+		lastError:            lastError,
+		lastAlertAt:          lastAlertAt,
+	}
+}
+
+// record 更新连续计数并在跨过 healthy<->unhealthy 边界时返回 transitioned=true。
+func (h *taskHealth) record(success bool, statusCode int, errStr string, failureThreshold, recoveryThreshold int) (transitioned, unhealthy bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastStatusCode = statusCode
+	h.lastError = errStr
+	if success {
+		h.consecutiveSuccesses++
+		h.consecutiveFailures = 0
+		if h.unhealthy && h.consecutiveSuccesses >= recoveryThreshold {
+			h.unhealthy = false
+			return true, false
+		}
+	} else {
+		h.consecutiveFailures++
+		h.consecutiveSuccesses = 0
+		if !h.unhealthy && h.consecutiveFailures >= failureThreshold {
+			h.unhealthy = true
+			return true, true
+		}
+	}
+	return false, h.unhealthy
+}
+
+// tryAlert 在距上次告警已超过 minInterval 时占用一次告警名额，用于抑制抖动。
+func (h *taskHealth) tryAlert(minInterval time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	if !h.lastAlertAt.IsZero() && now.Sub(h.lastAlertAt) < minInterval {
+		return false
+	}
+	h.lastAlertAt = now
+	return true
+}
+
+func (h *taskHealth) snapshot() (unhealthy bool, failures, successes, lastStatus int, lastError string, lastAlertAt time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.unhealthy, h.consecutiveFailures, h.consecutiveSuccesses, h.lastStatusCode, h.lastError, h.lastAlertAt
+}
+
+// fireNotify 在健康状态发生跃迁时异步投递一次 webhook，失败只记录日志、不重试、不影响任务执行。
+func (s *Scheduler) fireNotify(t *Task, unhealthy bool, statusCode int, errStr string) {
+	if !t.health.tryAlert(t.Notify.minInterval()) {
+		return
+	}
+	state := "healthy"
+	if unhealthy {
+		state = "unhealthy"
+	}
+	ev := notifyEvent{
+		TaskID:      t.ID,
+		Description: t.Description,
+		URL:         t.URL,
+		State:       state,
+		StatusCode:  statusCode,
+		Error:       errStr,
+		RunCount:    atomic.LoadUint64(&t.runCount),
+		Message:     t.Notify.renderMessage(t, state, statusCode, errStr),
+	}
+	body, err := json.Marshal(t.Notify.shape(ev))
+	if err != nil {
+		log.Printf("[task %s] notify marshal error: %v", t.ID, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeoutSeconds*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.Notify.URL, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[task %s] notify build request error: %v", t.ID, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("[task %s] notify webhook error: %v", t.ID, err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("[task %s] notify webhook returned status %s", t.ID, resp.Status)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskHealthRecordFailureTransition(t *testing.T) {
+	h := newTaskHealth(false, 0, 0, 0, "", time.Time{})
+	const failureThreshold, recoveryThreshold = 3, 2
+
+	for i := 1; i < failureThreshold; i++ {
+		transitioned, unhealthy := h.record(false, 500, "boom", failureThreshold, recoveryThreshold)
+		if transitioned || unhealthy {
+			t.Fatalf("failure %d: got (transitioned=%v, unhealthy=%v), want (false, false) before threshold", i, transitioned, unhealthy)
+		}
+	}
+
+	transitioned, unhealthy := h.record(false, 500, "boom", failureThreshold, recoveryThreshold)
+	if !transitioned || !unhealthy {
+		t.Fatalf("failure %d: got (transitioned=%v, unhealthy=%v), want (true, true) at threshold", failureThreshold, transitioned, unhealthy)
+	}
+
+	// 已经 unhealthy 时继续失败不应再次跃迁。
+	transitioned, unhealthy = h.record(false, 500, "boom", failureThreshold, recoveryThreshold)
+	if transitioned || !unhealthy {
+		t.Fatalf("extra failure while unhealthy: got (transitioned=%v, unhealthy=%v), want (false, true)", transitioned, unhealthy)
+	}
+}
+
+func TestTaskHealthRecordRecoveryTransition(t *testing.T) {
+	h := newTaskHealth(true, 3, 0, 500, "boom", time.Time{})
+	const failureThreshold, recoveryThreshold = 3, 2
+
+	transitioned, unhealthy := h.record(true, 200, "", failureThreshold, recoveryThreshold)
+	if transitioned || !unhealthy {
+		t.Fatalf("success 1/%d: got (transitioned=%v, unhealthy=%v), want (false, true) before recovery threshold", recoveryThreshold, transitioned, unhealthy)
+	}
+
+	transitioned, unhealthy = h.record(true, 200, "", failureThreshold, recoveryThreshold)
+	if !transitioned || unhealthy {
+		t.Fatalf("success 2/%d: got (transitioned=%v, unhealthy=%v), want (true, false) at recovery threshold", recoveryThreshold, transitioned, unhealthy)
+	}
+
+	// 已经 healthy 时继续成功不应再次跃迁。
+	transitioned, unhealthy = h.record(true, 200, "", failureThreshold, recoveryThreshold)
+	if transitioned || unhealthy {
+		t.Fatalf("extra success while healthy: got (transitioned=%v, unhealthy=%v), want (false, false)", transitioned, unhealthy)
+	}
+}
+
+func TestTaskHealthRecordResetsOppositeCounter(t *testing.T) {
+	h := newTaskHealth(false, 0, 0, 0, "", time.Time{})
+	h.record(false, 500, "boom", 5, 1)
+	h.record(false, 500, "boom", 5, 1)
+	_, failures, successes, _, _, _ := h.snapshot()
+	if failures != 2 || successes != 0 {
+		t.Fatalf("after 2 failures: failures=%d successes=%d, want 2, 0", failures, successes)
+	}
+
+	// 一次成功应清零连续失败计数，即便尚未跨过 recoveryThreshold。
+	h.record(true, 200, "", 5, 3)
+	_, failures, successes, _, _, _ = h.snapshot()
+	if failures != 0 || successes != 1 {
+		t.Fatalf("after interleaved success: failures=%d successes=%d, want 0, 1", failures, successes)
+	}
+}
+
+func TestTaskHealthTryAlertSuppressesFlapping(t *testing.T) {
+	h := newTaskHealth(false, 0, 0, 0, "", time.Time{})
+	if !h.tryAlert(time.Minute) {
+		t.Fatal("first alert should never be suppressed")
+	}
+	if h.tryAlert(time.Minute) {
+		t.Fatal("second alert within minInterval should be suppressed")
+	}
+
+	h2 := newTaskHealth(false, 0, 0, 0, "", time.Now().Add(-2*time.Minute))
+	if !h2.tryAlert(time.Minute) {
+		t.Fatal("alert after minInterval has elapsed should be allowed")
+	}
+}
+
+func TestNotifyConfigThresholdDefaults(t *testing.T) {
+	var c *NotifyConfig
+	if got := c.failureThreshold(); got != 3 {
+		t.Errorf("nil config failureThreshold() = %d, want 3", got)
+	}
+	if got := c.recoveryThreshold(); got != 1 {
+		t.Errorf("nil config recoveryThreshold() = %d, want 1", got)
+	}
+	if got := c.minInterval(); got != 5*time.Minute {
+		t.Errorf("nil config minInterval() = %v, want 5m", got)
+	}
+
+	c = &NotifyConfig{FailureThreshold: 10, RecoveryThreshold: 4, MinIntervalSeconds: 30}
+	if got := c.failureThreshold(); got != 10 {
+		t.Errorf("failureThreshold() = %d, want 10", got)
+	}
+	if got := c.recoveryThreshold(); got != 4 {
+		t.Errorf("recoveryThreshold() = %d, want 4", got)
+	}
+	if got := c.minInterval(); got != 30*time.Second {
+		t.Errorf("minInterval() = %v, want 30s", got)
+	}
+}
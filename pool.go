@@ -0,0 +1,216 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/panjf2000/ants/v2"
+)
+
+// poolOverloadRetryDelay 是协程池暂时饱和（Submit 被非阻塞拒绝）时，
+// 任务下一次重试提交前等待的时长。
+const poolOverloadRetryDelay = 200 * time.Millisecond
+
+// 扩缩容特性：无论任务数量是几十个还是几万个，Run 循环中始终只有一个定时器
+// 协程在工作——到期任务被提交给 ants 协程池，而不是各自起一个长驻 goroutine，
+// 因此常驻 goroutine 数量是 O(MaxConcurrency)，与任务总数无关；堆操作
+// （Push/Pop/Remove）都是 O(log n)，n 为当前已调度任务数。协程池大小通过
+// MaxConcurrency 配置执行并发上限，调用 Tune 可在运行时原地调整，无需重启。
+// 需要注意的是 AddTask/RemoveTask 仍会在持有 s.mu 的情况下把整份任务列表
+// 重新序列化写入 config.json（见 saveToDiskLocked），单次调用是 O(n)；
+// 任务数很大且增删频繁时这部分磁盘 I/O 会是瓶颈，而不是堆或协程池本身。
+// 参见 pool_bench_test.go 中的基准测试，对比了不同任务规模下的调度吞吐。
+const defaultMaxConcurrency = 100
+
+// 任务的重叠执行策略：skip（默认，跳过本次）或 queue（阻塞等待上一次运行让出名额）。
+const (
+	overlapPolicySkip  = "skip"
+	overlapPolicyQueue = "queue"
+)
+
+func normalizeOverlapPolicy(p string) string {
+	if p == overlapPolicyQueue {
+		return overlapPolicyQueue
+	}
+	return overlapPolicySkip
+}
+
+// heapItem 是调度堆中的一项：某个任务下一次应当触发执行的时间。
+type heapItem struct {
+	taskID string
+	nextAt time.Time
+	index  int
+}
+
+// taskHeap 是按 nextAt 升序排列的最小堆，只能在持有 Scheduler.mu 时访问。
+type taskHeap []*heapItem
+
+func (h taskHeap) Len() int           { return len(h) }
+func (h taskHeap) Less(i, j int) bool { return h[i].nextAt.Before(h[j].nextAt) }
+func (h taskHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *taskHeap) Push(x any) {
+	item := x.(*heapItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *taskHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
+}
+
+// computeNext 计算任务从 from 起下一次应当触发的时间：cron 任务交给缓存的 cron.Schedule，
+// 固定间隔任务直接累加 IntervalSeconds。
+func (t *Task) computeNext(from time.Time) time.Time {
+	if t.schedule != nil {
+		return t.schedule.Next(from.In(t.loc))
+	}
+	return from.Add(time.Duration(t.IntervalSeconds) * time.Second)
+}
+
+// scheduleLocked 把任务加入调度堆，调用方必须持有 s.mu。
+func (s *Scheduler) scheduleLocked(t *Task, at time.Time) {
+	item := &heapItem{taskID: t.ID, nextAt: at}
+	s.heapIdx[t.ID] = item
+	heap.Push(&s.heapItems, item)
+	s.wakeLocked()
+}
+
+// unscheduleLocked 把任务从调度堆中移除（如果存在），调用方必须持有 s.mu。
+func (s *Scheduler) unscheduleLocked(id string) {
+	item, ok := s.heapIdx[id]
+	if !ok {
+		return
+	}
+	heap.Remove(&s.heapItems, item.index)
+	delete(s.heapIdx, id)
+}
+
+// wakeLocked 唤醒 Run 的定时器协程，令其重新计算下一次等待时长；调用方必须持有 s.mu。
+func (s *Scheduler) wakeLocked() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run 是调度器的核心循环：单个定时器协程弹出到期任务，并把执行提交给协程池，
+// 从而不必再为每个任务各自占用一个 goroutine。新增任务（scheduleLocked）会通过 wake
+// 唤醒本循环重新计算下一次等待时长；暂停/删除只把任务移出堆、不唤醒本循环，
+// 定时器按原时间触发后 dispatchDue 找不到到期任务，再重新计算等待时长。
+// 因而扩缩容只需常数个额外 goroutine。
+func (s *Scheduler) Run(ctx context.Context) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		wait := time.Hour
+		if len(s.heapItems) > 0 {
+			if w := time.Until(s.heapItems[0].nextAt); w > 0 {
+				wait = w
+			} else {
+				wait = 0
+			}
+		}
+		s.mu.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+			continue
+		case <-timer.C:
+			s.dispatchDue()
+		}
+	}
+}
+
+// dispatchDue 弹出所有到期任务，计算它们的下一次触发时间并重新入堆，
+// 然后把实际执行提交给协程池。
+func (s *Scheduler) dispatchDue() {
+	now := time.Now()
+	var due []*Task
+
+	s.mu.Lock()
+	for len(s.heapItems) > 0 && !s.heapItems[0].nextAt.After(now) {
+		item := heap.Pop(&s.heapItems).(*heapItem)
+		delete(s.heapIdx, item.taskID)
+		t, ok := s.tasks[item.taskID]
+		if !ok || t.paused {
+			continue
+		}
+		due = append(due, t)
+		s.scheduleLocked(t, t.computeNext(now))
+	}
+	s.mu.Unlock()
+
+	for _, t := range due {
+		t := t
+		if err := s.pool.Submit(func() { s.runOnce(t) }); err != nil {
+			if errors.Is(err, ants.ErrPoolOverload) {
+				log.Printf("[task %s] worker pool saturated, retrying submit in %s", t.ID, poolOverloadRetryDelay)
+				s.requeueSoon(t, now)
+				continue
+			}
+			log.Printf("[task %s] submit to worker pool failed: %v", t.ID, err)
+		}
+	}
+}
+
+// requeueSoon 在协程池饱和、Submit 被非阻塞拒绝时，把任务的下一次触发时间
+// 提前到 poolOverloadRetryDelay 之后重试，取代该任务刚被安排的常规下一次
+// 触发时间，从而不阻塞调度循环，也不会丢失这次到期的执行。
+func (s *Scheduler) requeueSoon(t *Task, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unscheduleLocked(t.ID)
+	s.scheduleLocked(t, now.Add(poolOverloadRetryDelay))
+}
+
+// runOnce 在协程池的工作协程中运行一次任务，遵循 MaxInFlight/OverlapPolicy，
+// 并通过任务自身的 ctx 支持 PauseTask/RemoveTask 取消正在进行的执行。
+func (s *Scheduler) runOnce(t *Task) {
+	if t.OverlapPolicy == overlapPolicyQueue {
+		select {
+		case t.sem <- struct{}{}:
+		case <-t.ctx.Done():
+			return
+		}
+	} else {
+		select {
+		case t.sem <- struct{}{}:
+		default:
+			log.Printf("[task %s] skipped: previous run(s) still in flight (max_in_flight=%d)", t.ID, cap(t.sem))
+			return
+		}
+	}
+	defer func() { <-t.sem }()
+
+	select {
+	case <-t.ctx.Done():
+		return
+	default:
+	}
+	s.executeOnce(t)
+}
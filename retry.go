@@ -0,0 +1,133 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicyConfig 是重试策略的线路表示（与 persistedTask/AddTaskReq 一致，使用秒数）。
+type RetryPolicyConfig struct {
+	MaxAttempts           int     `json:"max_attempts,omitempty"`
+	InitialBackoffSeconds float64 `json:"initial_backoff_seconds,omitempty"`
+	MaxBackoffSeconds     float64 `json:"max_backoff_seconds,omitempty"`
+	Multiplier            float64 `json:"multiplier,omitempty"`
+	JitterFraction        float64 `json:"jitter_fraction,omitempty"`
+}
+
+// RetryPolicy 是 executeOnce 使用的运行期策略。
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	JitterFraction float64
+}
+
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2.0,
+		JitterFraction: 0.1,
+	}
+}
+
+// toRetryPolicy 将线路配置转换为运行期策略；缺省/零值字段回落到默认策略。
+func (c *RetryPolicyConfig) toRetryPolicy() RetryPolicy {
+	p := defaultRetryPolicy()
+	if c == nil {
+		return p
+	}
+	if c.MaxAttempts > 0 {
+		p.MaxAttempts = c.MaxAttempts
+	}
+	if c.InitialBackoffSeconds > 0 {
+		p.InitialBackoff = time.Duration(c.InitialBackoffSeconds * float64(time.Second))
+	}
+	if c.MaxBackoffSeconds > 0 {
+		p.MaxBackoff = time.Duration(c.MaxBackoffSeconds * float64(time.Second))
+	}
+	if c.Multiplier > 0 {
+		p.Multiplier = c.Multiplier
+	}
+	if c.JitterFraction > 0 {
+		p.JitterFraction = c.JitterFraction
+	}
+	return p
+}
+
+func (p RetryPolicy) toConfig() RetryPolicyConfig {
+	return RetryPolicyConfig{
+		MaxAttempts:           p.MaxAttempts,
+		InitialBackoffSeconds: p.InitialBackoff.Seconds(),
+		MaxBackoffSeconds:     p.MaxBackoff.Seconds(),
+		Multiplier:            p.Multiplier,
+		JitterFraction:        p.JitterFraction,
+	}
+}
+
+// backoff 计算第 attempt 次重试（从 0 开始计数）的等待时长：
+// min(MaxBackoff, InitialBackoff * Multiplier^attempt)，再叠加 [1-JitterFraction, 1+JitterFraction] 的均匀抖动。
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt))
+	if maxD := float64(p.MaxBackoff); d > maxD {
+		d = maxD
+	}
+	if p.JitterFraction > 0 {
+		lo := 1 - p.JitterFraction
+		hi := 1 + p.JitterFraction
+		d *= lo + rand.Float64()*(hi-lo)
+	}
+	return time.Duration(d)
+}
+
+type outcomeClass int
+
+const (
+	outcomeSuccess outcomeClass = iota
+	outcomeClientError
+	outcomeTransient
+)
+
+// classifyOutcome 将一次执行结果分类：成功（2xx/3xx）、客户端错误（4xx，但 408/429 除外，不重试）、
+// 瞬时错误（网络错误、408、429、5xx，可重试）。
+func classifyOutcome(statusCode int, err error) outcomeClass {
+	if err != nil {
+		return outcomeTransient
+	}
+	switch {
+	case statusCode >= 200 && statusCode < 400:
+		return outcomeSuccess
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests:
+		return outcomeTransient
+	case statusCode >= 500:
+		return outcomeTransient
+	default:
+		return outcomeClientError
+	}
+}
+
+// retryAfterDelay 解析响应的 Retry-After 头（秒数或 HTTP-date 两种形式），不检查状态码；
+// executeOnce 对任何可重试的响应（包括 429/503）都会用它取代计算出的退避时长。
+func retryAfterDelay(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
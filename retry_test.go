@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassifyOutcome(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		err        error
+		want       outcomeClass
+	}{
+		{"network error", 0, errors.New("dial tcp: timeout"), outcomeTransient},
+		{"200 ok", 200, nil, outcomeSuccess},
+		{"204 no content", 204, nil, outcomeSuccess},
+		{"399 redirect boundary", 399, nil, outcomeSuccess},
+		{"400 bad request", 400, nil, outcomeClientError},
+		{"404 not found", 404, nil, outcomeClientError},
+		{"408 request timeout", http.StatusRequestTimeout, nil, outcomeTransient},
+		{"429 too many requests", http.StatusTooManyRequests, nil, outcomeTransient},
+		{"499 client error boundary", 499, nil, outcomeClientError},
+		{"500 internal error", 500, nil, outcomeTransient},
+		{"503 unavailable", 503, nil, outcomeTransient},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := classifyOutcome(c.statusCode, c.err); got != c.want {
+				t.Errorf("classifyOutcome(%d, %v) = %v, want %v", c.statusCode, c.err, got, c.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2.0,
+		JitterFraction: 0,
+	}
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second}, // 16s 被 MaxBackoff 截断
+		{10, 10 * time.Second},
+	}
+	for _, c := range cases {
+		if got := p.backoff(c.attempt); got != c.want {
+			t.Errorf("backoff(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffJitterBounds(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2.0,
+		JitterFraction: 0.25,
+	}
+	base := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(3))
+	lo := time.Duration(base * 0.75)
+	hi := time.Duration(base * 1.25)
+	for i := 0; i < 200; i++ {
+		d := p.backoff(3)
+		if d < lo || d > hi {
+			t.Fatalf("backoff(3) = %v, want within [%v, %v]", d, lo, hi)
+		}
+	}
+}
+
+func TestToRetryPolicyDefaults(t *testing.T) {
+	var cfg *RetryPolicyConfig
+	p := cfg.toRetryPolicy()
+	if p != defaultRetryPolicy() {
+		t.Errorf("nil config should produce defaultRetryPolicy, got %+v", p)
+	}
+
+	partial := &RetryPolicyConfig{MaxAttempts: 5}
+	p = partial.toRetryPolicy()
+	want := defaultRetryPolicy()
+	want.MaxAttempts = 5
+	if p != want {
+		t.Errorf("partial config = %+v, want %+v (other fields fall back to defaults)", p, want)
+	}
+}
+
+func TestRetryAfterDelaySeconds(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"5"}}}
+	d, ok := retryAfterDelay(resp)
+	if !ok || d != 5*time.Second {
+		t.Errorf("retryAfterDelay(5) = %v, %v, want 5s, true", d, ok)
+	}
+}
+
+func TestRetryAfterDelayMissing(t *testing.T) {
+	if _, ok := retryAfterDelay(nil); ok {
+		t.Error("retryAfterDelay(nil) should report no delay")
+	}
+	resp := &http.Response{Header: http.Header{}}
+	if _, ok := retryAfterDelay(resp); ok {
+		t.Error("retryAfterDelay with no header should report no delay")
+	}
+}